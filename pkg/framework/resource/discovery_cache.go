@@ -0,0 +1,180 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+// defaultDiscoveryCacheTTL is how long a DiscoveryCache serves a snapshot before it considers it stale and re-fetches from the server.
+const defaultDiscoveryCacheTTL = 10 * time.Minute
+
+// discoveryCacheFileName is the name of the on-disk snapshot written under a DiscoveryCache's cache directory.
+const discoveryCacheFileName = "servergroups.json"
+
+// DiscoveryCache memoizes the result of a ServerResources() call keyed by an ETag derived from the response, and persists that result to an on-disk cache so a freshly started process doesn't pay for full discovery before its first TTL tick.
+type DiscoveryCache struct {
+	discovery discovery.DiscoveryInterface
+	cacheDir  string
+	ttl       time.Duration
+
+	mu        sync.Mutex
+	snapshot  []*v1.APIResourceList
+	etag      string
+	fetchedAt time.Time
+}
+
+// NewDiscoveryCache returns a DiscoveryCache backed by disc. host identifies the server the cache is for and is used to namespace the on-disk cache directory under ~/.kube/cache/discovery; it may be empty, in which case the cache is kept in memory only. A ttl of zero uses defaultDiscoveryCacheTTL.
+func NewDiscoveryCache(disc discovery.DiscoveryInterface, host string, ttl time.Duration) *DiscoveryCache {
+	if ttl <= 0 {
+		ttl = defaultDiscoveryCacheTTL
+	}
+	return &DiscoveryCache{
+		discovery: disc,
+		cacheDir:  discoveryCacheDir(host),
+		ttl:       ttl,
+	}
+}
+
+// ServerResources returns the cached discovery snapshot, refreshing it from the server first if the cache is empty or older than its TTL. A failed refresh of an otherwise populated cache returns the stale snapshot rather than an error, since stale discovery data is almost always more useful than none.
+func (c *DiscoveryCache) ServerResources() ([]*v1.APIResourceList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.snapshot != nil && time.Since(c.fetchedAt) < c.ttl {
+		return c.snapshot, nil
+	}
+
+	if c.snapshot == nil {
+		if disk, etag, fetchedAt, err := c.loadDisk(); err == nil {
+			c.snapshot, c.etag, c.fetchedAt = disk, etag, fetchedAt
+			if time.Since(c.fetchedAt) < c.ttl {
+				return c.snapshot, nil
+			}
+		}
+	}
+
+	gvs, err := c.discovery.ServerResources()
+	if err != nil {
+		if c.snapshot != nil {
+			return c.snapshot, nil
+		}
+		return nil, err
+	}
+
+	etag := etagFor(gvs)
+	if etag == c.etag && c.snapshot != nil {
+		c.fetchedAt = time.Now()
+		return c.snapshot, nil
+	}
+
+	c.fetchedAt = time.Now()
+	c.snapshot, c.etag = gvs, etag
+	c.saveDisk(gvs, etag, c.fetchedAt)
+	return c.snapshot, nil
+}
+
+// Invalidate drops the in-memory snapshot and removes the on-disk copy, if any, so the next ServerResources call is forced to hit the live discovery endpoint. Clearing only the in-memory fields would leave the on-disk file saveDisk already wrote in place, and since ServerResources loads from disk before falling back to a live call, callers like Watch would just keep reloading that same stale snapshot forever.
+func (c *DiscoveryCache) Invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.snapshot = nil
+	c.etag = ""
+	c.fetchedAt = time.Time{}
+	if c.cacheDir != "" {
+		_ = os.Remove(filepath.Join(c.cacheDir, discoveryCacheFileName))
+	}
+}
+
+// loadDisk returns the on-disk snapshot along with the FetchedAt timestamp it was originally written with, so the caller can judge its age against the TTL rather than treating a reload as a fresh fetch.
+func (c *DiscoveryCache) loadDisk() ([]*v1.APIResourceList, string, time.Time, error) {
+	if c.cacheDir == "" {
+		return nil, "", time.Time{}, os.ErrNotExist
+	}
+	data, err := os.ReadFile(filepath.Join(c.cacheDir, discoveryCacheFileName))
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	var file discoveryCacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, "", time.Time{}, err
+	}
+	return file.Resources, file.ETag, file.FetchedAt, nil
+}
+
+func (c *DiscoveryCache) saveDisk(gvs []*v1.APIResourceList, etag string, fetchedAt time.Time) {
+	if c.cacheDir == "" {
+		return
+	}
+	if err := os.MkdirAll(c.cacheDir, 0755); err != nil {
+		return
+	}
+	data, err := json.Marshal(discoveryCacheFile{ETag: etag, Resources: gvs, FetchedAt: fetchedAt})
+	if err != nil {
+		return
+	}
+	// Best-effort: a failed write just means the next cold start re-runs discovery.
+	_ = os.WriteFile(filepath.Join(c.cacheDir, discoveryCacheFileName), data, 0644)
+}
+
+// discoveryCacheFile is the on-disk representation of a DiscoveryCache snapshot. FetchedAt is persisted so a reload on process restart is judged against the TTL using the original fetch time, not the moment it happened to be loaded.
+type discoveryCacheFile struct {
+	ETag      string                `json:"etag"`
+	Resources []*v1.APIResourceList `json:"resources"`
+	FetchedAt time.Time             `json:"fetchedAt"`
+}
+
+// discoveryCacheDir returns the on-disk cache directory for host, or "" if it can't be determined.
+func discoveryCacheDir(host string) string {
+	if host == "" {
+		return ""
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "cache", "discovery", sanitizeHost(host))
+}
+
+// sanitizeHost turns a server host (e.g. "https://1.2.3.4:6443") into something safe to use as a directory name.
+func sanitizeHost(host string) string {
+	return strings.NewReplacer("://", "_", ":", "_", "/", "_").Replace(host)
+}
+
+// etagFor derives a cheap content hash of gvs to use as a change-detection key, standing in for a real server-provided ETag/ResourceVersion until the aggregated discovery endpoint is wired in.
+func etagFor(gvs []*v1.APIResourceList) string {
+	h := fnv.New64a()
+	for _, gv := range gvs {
+		io.WriteString(h, gv.GroupVersion)
+		for _, r := range gv.APIResources {
+			io.WriteString(h, r.Name)
+			io.WriteString(h, strings.Join(r.Verbs, ","))
+		}
+	}
+	return strconv.FormatUint(h.Sum64(), 16)
+}
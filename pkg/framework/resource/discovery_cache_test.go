@@ -0,0 +1,103 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/discovery"
+)
+
+// fakeDiscovery implements discovery.DiscoveryInterface by embedding it unset; only ServerResources is exercised by DiscoveryCache, so every other method would panic if called.
+type fakeDiscovery struct {
+	discovery.DiscoveryInterface
+	resources []*v1.APIResourceList
+	err       error
+	calls     int
+}
+
+func (f *fakeDiscovery) ServerResources() ([]*v1.APIResourceList, error) {
+	f.calls++
+	return f.resources, f.err
+}
+
+func TestDiscoveryCacheServesWithinTTL(t *testing.T) {
+	fake := &fakeDiscovery{resources: []*v1.APIResourceList{{GroupVersion: "v1"}}}
+	c := &DiscoveryCache{discovery: fake, ttl: 50 * time.Millisecond}
+
+	if _, err := c.ServerResources(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.ServerResources(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("expected a single live call while within TTL, got %d", fake.calls)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if _, err := c.ServerResources(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.calls != 2 {
+		t.Fatalf("expected a live call once the TTL expired, got %d", fake.calls)
+	}
+}
+
+func TestDiscoveryCacheInvalidateForcesLiveCall(t *testing.T) {
+	fake := &fakeDiscovery{resources: []*v1.APIResourceList{{GroupVersion: "v1"}}}
+	c := &DiscoveryCache{discovery: fake, cacheDir: t.TempDir(), ttl: time.Hour}
+
+	if _, err := c.ServerResources(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.calls != 1 {
+		t.Fatalf("expected one live call, got %d", fake.calls)
+	}
+
+	c.Invalidate()
+
+	if _, err := c.ServerResources(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.calls != 2 {
+		t.Fatalf("Invalidate should force a live discovery call rather than reloading the on-disk snapshot it just wrote, got %d calls", fake.calls)
+	}
+}
+
+func TestDiscoveryCacheLoadDiskHonorsOriginalFetchedAt(t *testing.T) {
+	fake := &fakeDiscovery{resources: []*v1.APIResourceList{{GroupVersion: "v1"}}}
+	dir := t.TempDir()
+
+	writer := &DiscoveryCache{discovery: fake, cacheDir: dir, ttl: time.Hour}
+	if _, err := writer.ServerResources(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Back-date the on-disk snapshot to simulate a process that was restarted long after the snapshot was written.
+	writer.fetchedAt = time.Now().Add(-time.Hour)
+	writer.saveDisk(writer.snapshot, writer.etag, writer.fetchedAt)
+
+	reader := &DiscoveryCache{discovery: fake, cacheDir: dir, ttl: time.Minute}
+	if _, err := reader.ServerResources(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.calls != 2 {
+		t.Fatalf("expected loading a stale on-disk snapshot to trigger a live call rather than serve it for a fresh TTL window, got %d calls", fake.calls)
+	}
+}
@@ -21,7 +21,10 @@ The starting point should be the creation of a new Parser object, which can then
 
 For example:
 
-	p := resource.NewParser()
+	p, err := resource.NewParserFromConfig(cfg)
+	if err != nil {
+		panic(err)
+	}
 	r, err := p.Resources()
 	if err != nil {
 		panic(err)
@@ -59,7 +62,10 @@ The following (admittedly ludicrous) example implements a filter that excludes a
 		return true
 	}
 	func main() {
-		p := resource.NewParser()
+		p, err := resource.NewParserFromConfig(cfg)
+		if err != nil {
+			panic(err)
+		}
 		r, err := p.Resources()
 		if err != nil {
 			panic(err)
@@ -17,9 +17,13 @@ limitations under the License.
 package resource
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/kubectl/pkg/framework/merge"
 	"k8s.io/kubernetes/pkg/kubectl/apply"
 )
@@ -140,3 +144,188 @@ type prefixStrategy struct {
 func (fs *prefixStrategy) MergePrimitive(element apply.PrimitiveElement) (apply.Result, error) {
 	return apply.Result{MergedResult: fmt.Sprintf("%s%v", fs.prefix, element.GetRemote())}, nil
 }
+
+// protectedGVRs is the set of built-in resources that UnprotectedMatcher treats as off limits.
+var protectedGVRs = []schema.GroupVersionResource{
+	{Group: "", Version: "*", Resource: "pods"},
+	{Group: "", Version: "*", Resource: "services"},
+	{Group: "", Version: "*", Resource: "secrets"},
+	{Group: "", Version: "*", Resource: "namespaces"},
+	{Group: "", Version: "*", Resource: "nodes"},
+}
+
+// GVRMatcher is a Filter that accepts resources and subresources whose GroupVersionResource matches at least one of its Patterns. Any of the Group, Version, or Resource fields of a pattern may be "*" to match anything in that position.
+type GVRMatcher struct {
+	emptyFilter
+	Patterns []schema.GroupVersionResource
+}
+
+// NewGVRMatcher returns a new GVRMatcher accepting resources matching any of the given GroupVersionResource patterns.
+func NewGVRMatcher(patterns ...schema.GroupVersionResource) *GVRMatcher {
+	return &GVRMatcher{Patterns: patterns}
+}
+
+// CRDMatcher returns a GVRMatcher accepting only the CustomResourceDefinitions currently installed in the cluster reachable through apiextensionsClient.
+func CRDMatcher(ctx context.Context, apiextensionsClient apiextensionsclientset.Interface) (*GVRMatcher, error) {
+	crds, err := apiextensionsClient.ApiextensionsV1().CustomResourceDefinitions().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	patterns := make([]schema.GroupVersionResource, 0, len(crds.Items))
+	for _, crd := range crds.Items {
+		patterns = append(patterns, schema.GroupVersionResource{
+			Group:    crd.Spec.Group,
+			Version:  "*",
+			Resource: crd.Spec.Names.Plural,
+		})
+	}
+	return NewGVRMatcher(patterns...), nil
+}
+
+// UnprotectedMatcher returns a Filter accepting every resource and subresource except those backed by a protected built-in GVR (pods, services, secrets, namespaces, nodes).
+func UnprotectedMatcher() Filter {
+	return NewNotFilter(NewGVRMatcher(protectedGVRs...))
+}
+
+func (m *GVRMatcher) Resource(r *Resource) bool {
+	return m.matches(r.Resource.Group, r.Resource.Version, r.Resource.Name)
+}
+
+func (m *GVRMatcher) SubResource(sr *SubResource) bool {
+	return m.matches(sr.Resource.Group, sr.Resource.Version, sr.Parent.Resource.Name)
+}
+
+// matches reports whether group, version, and res satisfy any of the matcher's patterns, short-circuiting on "*" components so callers don't pay for a string compare against a wildcard.
+func (m *GVRMatcher) matches(group, version, res string) bool {
+	for _, p := range m.Patterns {
+		if matchGVRComponent(p.Group, group) && matchGVRComponent(p.Version, version) && matchGVRComponent(p.Resource, res) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchGVRComponent(pattern, value string) bool {
+	return pattern == "*" || pattern == value
+}
+
+// gvkFilter is a Filter that accepts resources and subresources whose GroupVersionKind matches any of its gvks.
+type gvkFilter struct {
+	emptyFilter
+	gvks []schema.GroupVersionKind
+}
+
+// NewGVKFilter returns a Filter accepting resources and subresources whose GroupVersionKind matches any of the given GVKs.
+func NewGVKFilter(gvks ...schema.GroupVersionKind) Filter {
+	return &gvkFilter{gvks: gvks}
+}
+
+func (f *gvkFilter) Resource(r *Resource) bool {
+	return f.matches(r.ResourceGroupVersionKind())
+}
+
+func (f *gvkFilter) SubResource(sr *SubResource) bool {
+	return f.matches(sr.ResourceGroupVersionKind())
+}
+
+func (f *gvkFilter) matches(gvk schema.GroupVersionKind) bool {
+	for _, g := range f.gvks {
+		if g == gvk {
+			return true
+		}
+	}
+	return false
+}
+
+// categoryFilter is a Filter that accepts resources and subresources listing category among their Categories (e.g. "all").
+type categoryFilter struct {
+	emptyFilter
+	category string
+}
+
+// NewCategoryFilter returns a Filter accepting resources and subresources whose Categories field includes category.
+func NewCategoryFilter(category string) Filter {
+	return &categoryFilter{category: category}
+}
+
+func (f *categoryFilter) Resource(r *Resource) bool {
+	return stringSliceContains(r.Resource.Categories, f.category)
+}
+
+func (f *categoryFilter) SubResource(sr *SubResource) bool {
+	return stringSliceContains(sr.Resource.Categories, f.category)
+}
+
+// verbFilter is a Filter that accepts resources and subresources supporting every verb in verbs.
+type verbFilter struct {
+	emptyFilter
+	verbs []string
+}
+
+// NewVerbFilter returns a Filter accepting resources and subresources whose Verbs field includes every one of the given verbs.
+func NewVerbFilter(verbs ...string) Filter {
+	return &verbFilter{verbs: verbs}
+}
+
+func (f *verbFilter) Resource(r *Resource) bool {
+	return stringSliceContainsAll(r.Resource.Verbs, f.verbs)
+}
+
+func (f *verbFilter) SubResource(sr *SubResource) bool {
+	return stringSliceContainsAll(sr.Resource.Verbs, f.verbs)
+}
+
+// namespacedFilter is a Filter that accepts resources and subresources whose Namespaced field matches namespaced.
+type namespacedFilter struct {
+	emptyFilter
+	namespaced bool
+}
+
+// NewNamespacedFilter returns a Filter accepting resources and subresources whose Namespaced field equals namespaced.
+func NewNamespacedFilter(namespaced bool) Filter {
+	return &namespacedFilter{namespaced: namespaced}
+}
+
+func (f *namespacedFilter) Resource(r *Resource) bool {
+	return r.Resource.Namespaced == f.namespaced
+}
+
+func (f *namespacedFilter) SubResource(sr *SubResource) bool {
+	return sr.Resource.Namespaced == f.namespaced
+}
+
+// notFilter is a Filter that accepts exactly what its inner Filter rejects.
+type notFilter struct {
+	inner Filter
+}
+
+// NewNotFilter returns a Filter that inverts inner, accepting what inner rejects and rejecting what inner accepts.
+func NewNotFilter(inner Filter) Filter {
+	return &notFilter{inner: inner}
+}
+
+func (f *notFilter) Resource(r *Resource) bool {
+	return !f.inner.Resource(r)
+}
+
+func (f *notFilter) SubResource(sr *SubResource) bool {
+	return !f.inner.SubResource(sr)
+}
+
+func stringSliceContains(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if s == needle {
+			return true
+		}
+	}
+	return false
+}
+
+func stringSliceContainsAll(haystack []string, needles []string) bool {
+	for _, n := range needles {
+		if !stringSliceContains(haystack, n) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestGVRMatcherResource(t *testing.T) {
+	matcher := NewGVRMatcher(schema.GroupVersionResource{Group: "apps", Version: "*", Resource: "deployments"})
+
+	cases := []struct {
+		name   string
+		r      *Resource
+		accept bool
+	}{
+		{
+			name:   "matches with wildcard version",
+			r:      &Resource{Resource: v1.APIResource{Group: "apps", Version: "v1", Name: "deployments"}},
+			accept: true,
+		},
+		{
+			name:   "different resource is rejected",
+			r:      &Resource{Resource: v1.APIResource{Group: "apps", Version: "v1", Name: "replicasets"}},
+			accept: false,
+		},
+		{
+			name:   "different group is rejected",
+			r:      &Resource{Resource: v1.APIResource{Group: "batch", Version: "v1", Name: "deployments"}},
+			accept: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := matcher.Resource(c.r); got != c.accept {
+				t.Errorf("Resource(%+v) = %v, want %v", c.r.Resource, got, c.accept)
+			}
+		})
+	}
+}
+
+func TestGVRMatcherSubResource(t *testing.T) {
+	matcher := NewGVRMatcher(schema.GroupVersionResource{Group: "*", Version: "*", Resource: "pods"})
+	parent := &Resource{Resource: v1.APIResource{Group: "", Version: "v1", Name: "pods"}}
+	sr := &SubResource{Resource: v1.APIResource{Group: "", Version: "v1", Name: "pods/status"}, Parent: parent}
+
+	if !matcher.SubResource(sr) {
+		t.Error("expected pods/status to match via its parent resource's name")
+	}
+}
+
+func TestGVKFilter(t *testing.T) {
+	filter := NewGVKFilter(
+		schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"},
+	)
+
+	cases := []struct {
+		name   string
+		r      *Resource
+		accept bool
+	}{
+		{
+			name:   "matches one of the given GVKs",
+			r:      &Resource{Resource: v1.APIResource{Group: "", Version: "v1", Kind: "Pod"}},
+			accept: true,
+		},
+		{
+			name:   "kind not in the list is rejected",
+			r:      &Resource{Resource: v1.APIResource{Group: "", Version: "v1", Kind: "Service"}},
+			accept: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := filter.Resource(c.r); got != c.accept {
+				t.Errorf("Resource(%+v) = %v, want %v", c.r.Resource, got, c.accept)
+			}
+		})
+	}
+}
+
+func TestCategoryFilter(t *testing.T) {
+	filter := NewCategoryFilter("all")
+
+	cases := []struct {
+		name   string
+		r      *Resource
+		accept bool
+	}{
+		{
+			name:   "category present is accepted",
+			r:      &Resource{Resource: v1.APIResource{Categories: []string{"all", "apps"}}},
+			accept: true,
+		},
+		{
+			name:   "category absent is rejected",
+			r:      &Resource{Resource: v1.APIResource{Categories: []string{"apps"}}},
+			accept: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := filter.Resource(c.r); got != c.accept {
+				t.Errorf("Resource(%+v) = %v, want %v", c.r.Resource, got, c.accept)
+			}
+		})
+	}
+}
+
+func TestVerbFilter(t *testing.T) {
+	filter := NewVerbFilter("get", "list")
+
+	cases := []struct {
+		name   string
+		r      *Resource
+		accept bool
+	}{
+		{
+			name:   "has every required verb",
+			r:      &Resource{Resource: v1.APIResource{Verbs: []string{"get", "list", "watch"}}},
+			accept: true,
+		},
+		{
+			name:   "missing a required verb is rejected",
+			r:      &Resource{Resource: v1.APIResource{Verbs: []string{"get"}}},
+			accept: false,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := filter.Resource(c.r); got != c.accept {
+				t.Errorf("Resource(%+v) = %v, want %v", c.r.Resource, got, c.accept)
+			}
+		})
+	}
+}
+
+func TestNamespacedFilter(t *testing.T) {
+	namespaced := NewNamespacedFilter(true)
+	clusterScoped := NewNamespacedFilter(false)
+
+	nsResource := &Resource{Resource: v1.APIResource{Namespaced: true}}
+	clusterResource := &Resource{Resource: v1.APIResource{Namespaced: false}}
+
+	if !namespaced.Resource(nsResource) {
+		t.Error("expected a namespaced resource to be accepted by NewNamespacedFilter(true)")
+	}
+	if namespaced.Resource(clusterResource) {
+		t.Error("expected a cluster-scoped resource to be rejected by NewNamespacedFilter(true)")
+	}
+	if !clusterScoped.Resource(clusterResource) {
+		t.Error("expected a cluster-scoped resource to be accepted by NewNamespacedFilter(false)")
+	}
+}
+
+func TestNotFilter(t *testing.T) {
+	filter := NewNotFilter(NewCategoryFilter("all"))
+
+	matching := &Resource{Resource: v1.APIResource{Categories: []string{"all"}}}
+	nonMatching := &Resource{Resource: v1.APIResource{Categories: []string{"apps"}}}
+
+	if filter.Resource(matching) {
+		t.Error("expected NewNotFilter to reject what its inner filter accepts")
+	}
+	if !filter.Resource(nonMatching) {
+		t.Error("expected NewNotFilter to accept what its inner filter rejects")
+	}
+}
+
+func TestUnprotectedMatcher(t *testing.T) {
+	matcher := UnprotectedMatcher()
+
+	pod := &Resource{Resource: v1.APIResource{Group: "", Version: "v1", Name: "pods"}}
+	if matcher.Resource(pod) {
+		t.Error("expected pods to be rejected by UnprotectedMatcher")
+	}
+
+	configMap := &Resource{Resource: v1.APIResource{Group: "", Version: "v1", Name: "configmaps"}}
+	if !matcher.Resource(configMap) {
+		t.Error("expected configmaps to be accepted by UnprotectedMatcher")
+	}
+}
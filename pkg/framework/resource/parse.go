@@ -17,47 +17,205 @@ limitations under the License.
 package resource
 
 import (
+	"context"
 	"strings"
+	"sync"
+	"time"
 
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/kube-openapi/pkg/util/proto"
-	"k8s.io/kubectl/pkg/framework/internal/inject"
 	"k8s.io/kubernetes/pkg/kubectl/cmd/util/openapi"
 )
 
 // Parser is an object type that can be used to discover resources from an API server and parse them into indexed data structures.
 type Parser struct {
-	resources  openapi.Resources
-	discovery  discovery.DiscoveryInterface
-	rest       rest.Interface
-	apiGroup   string
-	apiVersion string
+	schemaSources  []SchemaSource
+	discovery      discovery.DiscoveryInterface
+	rest           rest.Interface
+	discoveryCache *DiscoveryCache
+
+	// restMapperMu guards restMapper, which Resources() rebuilds on every call while RESTMapper() reads it; Watch runs Resources() in a loop on its own goroutine, so both can race with a caller's own Resources()/RESTMapper() calls without it.
+	restMapperMu       sync.Mutex
+	restMapper         meta.RESTMapper
+	keepExternalMapper bool
 }
 
-// NewParser populates the fields of and returns a new Parser.
-func NewParser() Parser {
-	return Parser{
-		inject.FactorySingleton.GetResources(),
-		inject.FactorySingleton.GetDiscovery(),
-		inject.FactorySingleton.GetRest(),
-		inject.FactorySingleton.GetApiGroup(),
-		inject.FactorySingleton.GetApiVersion(),
+// NewParserFromConfig builds the discovery client, REST client, and OpenAPI v2 schema resources for the cluster described by cfg, and returns a Parser wired to them. Unlike the old package-level NewParser, which always pointed at the same process-wide inject.FactorySingleton, each call to NewParserFromConfig produces an independent Parser, so callers can hold one per cluster. Pass additional SchemaSources (v3, CRD, ...) beyond the default OpenAPI v2 one via sources.
+func NewParserFromConfig(cfg *rest.Config, sources ...SchemaSource) (*Parser, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	restClient, err := rest.UnversionedRESTClientFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	openAPIResources, err := openapi.NewOpenAPIGetter(discoveryClient).Get()
+	if err != nil {
+		return nil, err
 	}
+	allSources := append([]SchemaSource{NewOpenAPIV2SchemaSource(openAPIResources)}, sources...)
+	return &Parser{
+		schemaSources:  allSources,
+		discovery:      discoveryClient,
+		rest:           restClient,
+		discoveryCache: NewDiscoveryCache(discoveryClient, cfg.Host, 0),
+	}, nil
 }
 
-// Resources discovers and indexes resources from the API server.
-// It returns a map of resource name to resources matching that name ordered by preference as reported by the server.
+// NewParserFromRESTMapper builds a Parser around an already-constructed meta.RESTMapper, discovery client, and OpenAPI v2 resources. It's the bridge for callers - controller-runtime users, multi-context CLIs - that already maintain their own mapper and don't want Resources() silently replacing it with one built from this package's own discovery pass.
+func NewParserFromRESTMapper(mapper meta.RESTMapper, disc discovery.DiscoveryInterface, resources openapi.Resources) *Parser {
+	return &Parser{
+		schemaSources:      []SchemaSource{NewOpenAPIV2SchemaSource(resources)},
+		discovery:          disc,
+		discoveryCache:     NewDiscoveryCache(disc, "", 0),
+		restMapper:         mapper,
+		keepExternalMapper: true,
+	}
+}
+
+// RESTMapper returns the meta.RESTMapper built as a side effect of the most recent Resources() call, or the one supplied directly to NewParserFromRESTMapper. It resolves short names, singular/plural forms, and Kind<->Resource conversions so callers don't have to re-implement that logic on top of Resources().
+func (p *Parser) RESTMapper() meta.RESTMapper {
+	p.restMapperMu.Lock()
+	defer p.restMapperMu.Unlock()
+	return p.restMapper
+}
+
+// Resources discovers and indexes resources from the API server, taking the resource lists from the Parser's DiscoveryCache rather than hitting the server on every call.
+// It returns a map of resource name to resources matching that name ordered by preference as reported by the server. As a side effect, unless the Parser was built with NewParserFromRESTMapper, it also rebuilds the mapper returned by RESTMapper(); that rebuild queries the discovery client directly on every call and is not served from the DiscoveryCache.
 func (p *Parser) Resources() (Resources, error) {
-	gvs, err := p.discovery.ServerResources()
+	gvs, err := p.discoveryCache.ServerResources()
 	if err != nil {
 		return nil, err
 	}
 	resources, byGVR := p.indexResources(gvs)
-	err = p.attachSubResources(gvs, resources, byGVR)
-	return resources, err
+	if err := p.attachSubResources(gvs, resources, byGVR); err != nil {
+		return nil, err
+	}
+	if !p.keepExternalMapper && p.discovery != nil {
+		if mapper, err := p.buildRESTMapper(); err == nil {
+			p.restMapperMu.Lock()
+			p.restMapper = mapper
+			p.restMapperMu.Unlock()
+		}
+	}
+	return resources, nil
+}
+
+// buildRESTMapper builds a meta.PriorityRESTMapper (preferring the core v1 group, then each other group's preferred version) from the Parser's discovery client, the same way kubectl itself resolves short names and Kind<->Resource conversions.
+func (p *Parser) buildRESTMapper() (meta.RESTMapper, error) {
+	groupResources, err := restmapper.GetAPIGroupResources(p.discovery)
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDiscoveryRESTMapper(groupResources), nil
+}
+
+// watchPollInterval is how often Watch re-checks the discovery cache for changes.
+const watchPollInterval = 30 * time.Second
+
+// EventType describes how a GroupVersionResource changed between two discovery snapshots.
+type EventType string
+
+const (
+	// Added means the GVR was not present in the previous snapshot.
+	Added EventType = "ADDED"
+	// Removed means the GVR was present in the previous snapshot but is gone from the current one.
+	Removed EventType = "REMOVED"
+	// Changed means the GVR is present in both snapshots but its verbs or kind differ.
+	Changed EventType = "CHANGED"
+)
+
+// ResourcesEvent describes a single resource change detected by Watch.
+type ResourcesEvent struct {
+	Type     EventType
+	GVR      schema.GroupVersionResource
+	Resource *Resource
+}
+
+// Watch invalidates and re-indexes the Parser's DiscoveryCache every watchPollInterval, emitting a ResourcesEvent per GVR that was added, removed, or changed since the previous poll. The returned channel is closed once ctx is done.
+func (p *Parser) Watch(ctx context.Context) (<-chan ResourcesEvent, error) {
+	initial, err := p.Resources()
+	if err != nil {
+		return nil, err
+	}
+	events := make(chan ResourcesEvent)
+	last := indexByGVR(initial)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(watchPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.discoveryCache.Invalidate()
+				current, err := p.Resources()
+				if err != nil {
+					continue
+				}
+				next := indexByGVR(current)
+				emitResourceEvents(ctx, events, last, next)
+				last = next
+			}
+		}
+	}()
+	return events, nil
+}
+
+// indexByGVR flattens Resources into a map keyed by GroupVersionResource, taking the group and version from each Resource's ApiGroupVersion (the same group and version indexResources keys bygvr by) and the resource from its name.
+func indexByGVR(rs Resources) map[schema.GroupVersionResource]*Resource {
+	out := map[schema.GroupVersionResource]*Resource{}
+	for _, versions := range rs {
+		for _, r := range versions {
+			out[schema.GroupVersionResource{Group: r.ApiGroupVersion.Group, Version: r.ApiGroupVersion.Version, Resource: r.Resource.Name}] = r
+		}
+	}
+	return out
+}
+
+// emitResourceEvents diffs prev against next and sends an event for every GVR that was added, removed, or changed, stopping early if ctx is done.
+func emitResourceEvents(ctx context.Context, events chan<- ResourcesEvent, prev, next map[schema.GroupVersionResource]*Resource) {
+	for gvr, r := range next {
+		old, found := prev[gvr]
+		switch {
+		case !found:
+			sendResourceEvent(ctx, events, ResourcesEvent{Type: Added, GVR: gvr, Resource: r})
+		case !resourceEqual(old, r):
+			sendResourceEvent(ctx, events, ResourcesEvent{Type: Changed, GVR: gvr, Resource: r})
+		}
+	}
+	for gvr, r := range prev {
+		if _, found := next[gvr]; !found {
+			sendResourceEvent(ctx, events, ResourcesEvent{Type: Removed, GVR: gvr, Resource: r})
+		}
+	}
+}
+
+// resourceEqual reports whether two Resource snapshots for the same GVR are equivalent for watch purposes.
+func resourceEqual(a, b *Resource) bool {
+	if a.Resource.Kind != b.Resource.Kind || len(a.Resource.Verbs) != len(b.Resource.Verbs) {
+		return false
+	}
+	for i, v := range a.Resource.Verbs {
+		if b.Resource.Verbs[i] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sendResourceEvent(ctx context.Context, events chan<- ResourcesEvent, ev ResourcesEvent) {
+	select {
+	case events <- ev:
+	case <-ctx.Done():
+	}
 }
 
 // subResource returns a resource name, subresource name pair, and true if the resource is a subresource.
@@ -118,24 +276,15 @@ func (p *Parser) defaultGroupVersion(resource *v1.APIResource, group, version st
 	}
 }
 
-// isGroupVersionMatch returns false if either group or version doesn't match with the API.
-func (p *Parser) isGroupVersionMatch(group, version string) bool {
-	if len(p.apiGroup) > 0 && p.apiGroup != group {
-		return false
-	}
-	if len(p.apiVersion) > 0 && p.apiVersion != version {
-		return false
-	}
-	return true
-}
-
-// getOpenAPI retrieves a schema object from the API based on a GroupVersionResource triplet.
-func (p *Parser) getOpenAPI(group, version, kind string) (proto.Schema, bool) {
-	schema := p.resources.LookupResource(schema.GroupVersionKind{group, version, kind})
-	if schema == nil {
-		return nil, false
+// getOpenAPI retrieves a schema object for a GroupVersionKind by trying each of the Parser's schemaSources in order, returning the first hit along with the SchemaSourceKind that supplied it.
+func (p *Parser) getOpenAPI(group, version, kind string) (proto.Schema, SchemaSourceKind, bool) {
+	gvk := schema.GroupVersionKind{Group: group, Version: version, Kind: kind}
+	for _, source := range p.schemaSources {
+		if s, found := source.LookupResource(gvk); found {
+			return s, source.Kind(), true
+		}
 	}
-	return schema, true
+	return nil, "", false
 }
 
 // indexResources indexes into maps the resources from the API resource list. It returns a map indexed by resource name, and a map indexed by GroupVersionResource objects.
@@ -145,16 +294,13 @@ func (p *Parser) indexResources(gvs []*v1.APIResourceList) (map[string][]*Resour
 	// Find all resources
 	for _, gv := range gvs {
 		group, version := p.splitGroupVersion(gv.GroupVersion)
-		if !p.isGroupVersionMatch(group, version) {
-			continue
-		}
 		for _, r := range gv.APIResources {
 			p.defaultGroupVersion(&r, group, version)
 			name, isRes := p.resource(&r)
 			if !isRes {
 				continue
 			}
-			newSchema, found := p.getOpenAPI(group, version, r.Kind)
+			newSchema, source, found := p.getOpenAPI(group, version, r.Kind)
 			if !found {
 				continue
 			}
@@ -162,6 +308,7 @@ func (p *Parser) indexResources(gvs []*v1.APIResourceList) (map[string][]*Resour
 				Resource:        r,
 				ApiGroupVersion: schema.GroupVersion{group, version},
 				Schema:          newSchema,
+				SchemaSource:    source,
 			}
 			resources[name] = append(resources[name], newResource)
 			bygvr[schema.GroupVersionResource{group, version, r.Kind}] = newResource
@@ -178,16 +325,13 @@ func (p *Parser) attachSubResources(
 	// Find all subresources and attach to parents
 	for _, gv := range gvs {
 		group, version := p.splitGroupVersion(gv.GroupVersion)
-		if !p.isGroupVersionMatch(group, version) {
-			continue
-		}
 		for _, r := range gv.APIResources {
 			p.defaultGroupVersion(&r, group, version)
 			resourceName, _, isSubResource := p.subResource(&r)
 			if !isSubResource {
 				continue
 			}
-			newSchema, found := p.getOpenAPI(group, version, r.Kind)
+			newSchema, source, found := p.getOpenAPI(group, version, r.Kind)
 			if !found {
 				continue
 			}
@@ -202,6 +346,7 @@ func (p *Parser) attachSubResources(
 				Parent:          parent,
 				ApiGroupVersion: schema.GroupVersion{group, version},
 				Schema:          newSchema,
+				SchemaSource:    source,
 			}
 			parent.SubResources = append(parent.SubResources, subRes)
 		}
@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestResourceEqual(t *testing.T) {
+	a := &Resource{Resource: v1.APIResource{Kind: "Pod", Verbs: []string{"get", "list"}}}
+	b := &Resource{Resource: v1.APIResource{Kind: "Pod", Verbs: []string{"get", "list"}}}
+	c := &Resource{Resource: v1.APIResource{Kind: "Pod", Verbs: []string{"get"}}}
+	d := &Resource{Resource: v1.APIResource{Kind: "PodList", Verbs: []string{"get", "list"}}}
+
+	if !resourceEqual(a, b) {
+		t.Error("expected resources with identical kind and verbs to be equal")
+	}
+	if resourceEqual(a, c) {
+		t.Error("expected resources with different verbs to be unequal")
+	}
+	if resourceEqual(a, d) {
+		t.Error("expected resources with different kinds to be unequal")
+	}
+}
+
+func TestEmitResourceEvents(t *testing.T) {
+	pods := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "pods"}
+	services := schema.GroupVersionResource{Group: "", Version: "v1", Resource: "services"}
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+
+	prev := map[schema.GroupVersionResource]*Resource{
+		pods:     {Resource: v1.APIResource{Kind: "Pod", Verbs: []string{"get", "list"}}},
+		services: {Resource: v1.APIResource{Kind: "Service", Verbs: []string{"get"}}},
+	}
+	next := map[schema.GroupVersionResource]*Resource{
+		pods:    {Resource: v1.APIResource{Kind: "Pod", Verbs: []string{"get", "list", "watch"}}},
+		widgets: {Resource: v1.APIResource{Kind: "Widget", Verbs: []string{"get"}}},
+	}
+
+	events := make(chan ResourcesEvent, len(prev)+len(next))
+	emitResourceEvents(context.Background(), events, prev, next)
+	close(events)
+
+	got := map[schema.GroupVersionResource]EventType{}
+	for ev := range events {
+		got[ev.GVR] = ev.Type
+	}
+
+	if got[pods] != Changed {
+		t.Errorf("expected %v to be Changed, got %v", pods, got[pods])
+	}
+	if got[services] != Removed {
+		t.Errorf("expected %v to be Removed, got %v", services, got[services])
+	}
+	if got[widgets] != Added {
+		t.Errorf("expected %v to be Added, got %v", widgets, got[widgets])
+	}
+}
@@ -27,6 +27,8 @@ type Resource struct {
 	Resource        v1.APIResource
 	ApiGroupVersion schema.GroupVersion
 	openapi.Schema
+	// SchemaSource records which SchemaSource supplied Schema. Resources for which no source has a schema are left out of the index, so an indexed Resource always has one.
+	SchemaSource SchemaSourceKind
 	SubResources []*SubResource
 }
 
@@ -36,6 +38,8 @@ type SubResource struct {
 	Parent          *Resource
 	ApiGroupVersion schema.GroupVersion
 	openapi.Schema
+	// SchemaSource records which SchemaSource supplied Schema. Subresources for which no source has a schema are not attached, so an attached SubResource always has one.
+	SchemaSource SchemaSourceKind
 }
 
 func (r *Resource) HasField(path []string) bool {
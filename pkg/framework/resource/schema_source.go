@@ -0,0 +1,258 @@
+/*
+Copyright 2018 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclientset "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	"k8s.io/kubernetes/pkg/kubectl/cmd/util/openapi"
+)
+
+// SchemaSourceKind identifies which backend supplied a Resource or SubResource's schema.
+type SchemaSourceKind string
+
+const (
+	// SchemaSourceOpenAPIV2 means the schema came from the aggregated OpenAPI v2 document.
+	SchemaSourceOpenAPIV2 SchemaSourceKind = "OpenAPIV2"
+	// SchemaSourceOpenAPIV3 means the schema came from a per-GroupVersion OpenAPI v3 document.
+	SchemaSourceOpenAPIV3 SchemaSourceKind = "OpenAPIV3"
+	// SchemaSourceCRD means the schema was converted directly from a CustomResourceDefinition's openAPIV3Schema.
+	SchemaSourceCRD SchemaSourceKind = "CRD"
+)
+
+// SchemaSource looks up the schema for a GroupVersionKind. It returns ok=false, rather than an error, when the source simply has no schema for that GVK, so a chain of sources can fall through to the next one.
+type SchemaSource interface {
+	Kind() SchemaSourceKind
+	LookupResource(gvk schema.GroupVersionKind) (proto.Schema, bool)
+}
+
+// openAPIV2Source looks up schemas in the aggregated OpenAPI v2 document, same as the Parser has always done.
+type openAPIV2Source struct {
+	resources openapi.Resources
+}
+
+// NewOpenAPIV2SchemaSource returns a SchemaSource backed by the aggregated OpenAPI v2 document.
+func NewOpenAPIV2SchemaSource(resources openapi.Resources) SchemaSource {
+	return &openAPIV2Source{resources: resources}
+}
+
+func (s *openAPIV2Source) Kind() SchemaSourceKind {
+	return SchemaSourceOpenAPIV2
+}
+
+func (s *openAPIV2Source) LookupResource(gvk schema.GroupVersionKind) (proto.Schema, bool) {
+	if s.resources == nil {
+		return nil, false
+	}
+	found := s.resources.LookupResource(gvk)
+	if found == nil {
+		return nil, false
+	}
+	return found, true
+}
+
+// openAPIV3Source looks up schemas in the per-GroupVersion documents served at /openapi/v3/apis/<group>/<version>, which is where most CRD schemas actually live since the aggregated v2 document never carries them.
+type openAPIV3Source struct {
+	rest rest.Interface
+}
+
+// NewOpenAPIV3SchemaSource returns a SchemaSource backed by the OpenAPI v3 per-GroupVersion endpoints reachable through restClient.
+func NewOpenAPIV3SchemaSource(restClient rest.Interface) SchemaSource {
+	return &openAPIV3Source{rest: restClient}
+}
+
+func (s *openAPIV3Source) Kind() SchemaSourceKind {
+	return SchemaSourceOpenAPIV3
+}
+
+func (s *openAPIV3Source) LookupResource(gvk schema.GroupVersionKind) (proto.Schema, bool) {
+	if s.rest == nil {
+		return nil, false
+	}
+	data, err := s.rest.Get().AbsPath(v3SchemaPath(gvk.Group, gvk.Version)).DoRaw(context.Background())
+	if err != nil {
+		return nil, false
+	}
+	var doc openAPIV3Document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, false
+	}
+	def, found := doc.Components.Schemas[definitionName(gvk)]
+	if !found {
+		return nil, false
+	}
+	return newJSONSchema(def), true
+}
+
+// v3SchemaPath builds the per-GroupVersion OpenAPI v3 document path. The core group is served under /openapi/v3/api/<version> rather than /openapi/v3/apis/<group>/<version>.
+func v3SchemaPath(group, version string) string {
+	if group == "" {
+		return fmt.Sprintf("/openapi/v3/api/%s", version)
+	}
+	return fmt.Sprintf("/openapi/v3/apis/%s/%s", group, version)
+}
+
+// definitionName follows the naming convention Kubernetes uses for schema definitions in both the v2 and v3 OpenAPI documents.
+func definitionName(gvk schema.GroupVersionKind) string {
+	group := gvk.Group
+	if group == "" {
+		group = "core"
+	}
+	return fmt.Sprintf("io.k8s.%s.%s.%s", group, gvk.Version, gvk.Kind)
+}
+
+// openAPIV3Document is the minimal slice of an OpenAPI v3 document this package needs: its component schemas.
+type openAPIV3Document struct {
+	Components struct {
+		Schemas map[string]jsonSchemaProps `json:"schemas"`
+	} `json:"components"`
+}
+
+// jsonSchemaProps is the subset of a JSON Schema object needed to build a proto.Schema. It's shared by the OpenAPI v3 and CRD sources, since both describe schemas in the same shape.
+type jsonSchemaProps struct {
+	Type                 string                     `json:"type,omitempty"`
+	Format               string                     `json:"format,omitempty"`
+	Description          string                     `json:"description,omitempty"`
+	Properties           map[string]jsonSchemaProps `json:"properties,omitempty"`
+	Required             []string                   `json:"required,omitempty"`
+	Items                *jsonSchemaProps           `json:"items,omitempty"`
+	AdditionalProperties *jsonSchemaProps           `json:"additionalProperties,omitempty"`
+}
+
+// newJSONSchema converts a jsonSchemaProps into the proto.Schema tree the rest of this package already knows how to walk.
+func newJSONSchema(def jsonSchemaProps) proto.Schema {
+	base := proto.BaseSchema{Description: def.Description}
+	switch {
+	case len(def.Properties) > 0 || (def.Type == "object" && def.AdditionalProperties == nil):
+		fields := make(map[string]proto.Schema, len(def.Properties))
+		order := make([]string, 0, len(def.Properties))
+		for name, prop := range def.Properties {
+			fields[name] = newJSONSchema(prop)
+			order = append(order, name)
+		}
+		sort.Strings(order)
+		return &proto.Kind{BaseSchema: base, Fields: fields, RequiredFields: def.Required, FieldOrder: order}
+	case def.Type == "array" && def.Items != nil:
+		return &proto.Array{BaseSchema: base, SubType: newJSONSchema(*def.Items)}
+	case def.Type == "object" && def.AdditionalProperties != nil:
+		return &proto.Map{BaseSchema: base, SubType: newJSONSchema(*def.AdditionalProperties)}
+	case def.Type == "":
+		return &proto.Arbitrary{BaseSchema: base}
+	default:
+		return &proto.Primitive{BaseSchema: base, Type: def.Type, Format: def.Format}
+	}
+}
+
+// crdListCacheTTL bounds how long crdSource reuses a CustomResourceDefinitions list across LookupResource calls. Without it, a single indexResources pass - which calls LookupResource once per API resource in the cluster - would re-list CRDs once per resource.
+const crdListCacheTTL = 30 * time.Second
+
+// crdSource looks up a CRD's schema directly from its CustomResourceDefinition object (spec.versions[].schema.openAPIV3Schema), bypassing the OpenAPI document entirely. It's the most authoritative source for a CRD's own schema and works even against a server that hasn't indexed it into OpenAPI yet.
+type crdSource struct {
+	client apiextensionsclientset.Interface
+
+	mu        sync.Mutex
+	crds      []apiextensionsv1.CustomResourceDefinition
+	fetchedAt time.Time
+}
+
+// NewCRDSchemaSource returns a SchemaSource that resolves a GVK's schema by looking up the matching CustomResourceDefinition and converting its openAPIV3Schema.
+func NewCRDSchemaSource(client apiextensionsclientset.Interface) SchemaSource {
+	return &crdSource{client: client}
+}
+
+func (s *crdSource) Kind() SchemaSourceKind {
+	return SchemaSourceCRD
+}
+
+func (s *crdSource) LookupResource(gvk schema.GroupVersionKind) (proto.Schema, bool) {
+	if s.client == nil {
+		return nil, false
+	}
+	crds, err := s.list()
+	if err != nil {
+		return nil, false
+	}
+	for _, crd := range crds {
+		if crd.Spec.Group != gvk.Group || crd.Spec.Names.Kind != gvk.Kind {
+			continue
+		}
+		for _, v := range crd.Spec.Versions {
+			if v.Name != gvk.Version || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			return newJSONSchema(fromCRDProps(*v.Schema.OpenAPIV3Schema)), true
+		}
+	}
+	return nil, false
+}
+
+// list returns the cached CustomResourceDefinition list, re-listing from the server only once crdListCacheTTL has elapsed since the last successful list. A failed refresh falls back to the stale list, if there is one, rather than making every in-flight LookupResource call fail.
+func (s *crdSource) list() ([]apiextensionsv1.CustomResourceDefinition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.crds != nil && time.Since(s.fetchedAt) < crdListCacheTTL {
+		return s.crds, nil
+	}
+
+	list, err := s.client.ApiextensionsV1().CustomResourceDefinitions().List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		if s.crds != nil {
+			return s.crds, nil
+		}
+		return nil, err
+	}
+
+	s.crds, s.fetchedAt = list.Items, time.Now()
+	return s.crds, nil
+}
+
+// fromCRDProps converts a CRD's JSONSchemaProps into the jsonSchemaProps shape newJSONSchema knows how to build from.
+func fromCRDProps(p apiextensionsv1.JSONSchemaProps) jsonSchemaProps {
+	out := jsonSchemaProps{
+		Type:        p.Type,
+		Format:      p.Format,
+		Description: p.Description,
+		Required:    p.Required,
+	}
+	if len(p.Properties) > 0 {
+		out.Properties = make(map[string]jsonSchemaProps, len(p.Properties))
+		for name, prop := range p.Properties {
+			out.Properties[name] = fromCRDProps(prop)
+		}
+	}
+	if p.Items != nil && p.Items.Schema != nil {
+		items := fromCRDProps(*p.Items.Schema)
+		out.Items = &items
+	}
+	if p.AdditionalProperties != nil && p.AdditionalProperties.Schema != nil {
+		additional := fromCRDProps(*p.AdditionalProperties.Schema)
+		out.AdditionalProperties = &additional
+	}
+	return out
+}